@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"log"
+	"os/exec"
+	"strconv"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	alertCPUThreshold float64
+	alertMemThreshold float64
+	alertSustainedFor time.Duration
+	alertExec         string
+)
+
+var alertCmd = &cobra.Command{
+	Use:   "alert",
+	Short: "Watch live process usage and run a command when a threshold is sustained",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		runAlertWatch()
+		return nil
+	},
+}
+
+func init() {
+	alertCmd.Flags().Float64Var(&alertCPUThreshold, "cpu", 90, "CPU percent threshold")
+	alertCmd.Flags().Float64Var(&alertMemThreshold, "mem", 80, "memory percent threshold")
+	alertCmd.Flags().DurationVar(&alertSustainedFor, "for", 30*time.Second, "how long a threshold must be sustained before firing")
+	alertCmd.Flags().StringVar(&alertExec, "exec", "", "command to run when a process sustains a breach (invoked with PID and name as arguments)")
+	rootCmd.AddCommand(alertCmd)
+}
+
+// breach tracks how long a PID has continuously exceeded a threshold, so
+// alert only fires once per sustained breach rather than once per second.
+type breach struct {
+	since time.Time
+	fired bool
+}
+
+func runAlertWatch() {
+	breaches := map[int32]*breach{}
+
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		batch, _, err := sampleProcesses()
+		if err != nil {
+			log.Printf("Error sampling processes: %v\n", err)
+			continue
+		}
+
+		now := time.Now()
+		seen := make(map[int32]struct{}, len(batch))
+
+		for _, usage := range batch {
+			seen[usage.PID] = struct{}{}
+
+			if usage.CPUUsage < alertCPUThreshold && usage.MemUsage < alertMemThreshold {
+				delete(breaches, usage.PID)
+				continue
+			}
+
+			b, tracked := breaches[usage.PID]
+			if !tracked {
+				breaches[usage.PID] = &breach{since: now}
+				continue
+			}
+			if !b.fired && now.Sub(b.since) >= alertSustainedFor {
+				b.fired = true
+				fireAlert(usage)
+			}
+		}
+
+		for pid := range breaches {
+			if _, ok := seen[pid]; !ok {
+				delete(breaches, pid)
+			}
+		}
+	}
+}
+
+func fireAlert(usage ProcessUsage) {
+	if alertExec == "" {
+		log.Printf("ALERT: pid %d (%s) sustained cpu=%.1f%% mem=%.1f%% for %s\n",
+			usage.PID, usage.Name, usage.CPUUsage, usage.MemUsage, alertSustainedFor)
+		return
+	}
+
+	cmd := exec.Command(alertExec, strconv.Itoa(int(usage.PID)), usage.Name)
+	if err := cmd.Run(); err != nil {
+		log.Printf("Error running alert hook for pid %d: %v\n", usage.PID, err)
+	}
+}
@@ -0,0 +1,282 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/shirou/gopsutil/v3/cpu"
+	"github.com/shirou/gopsutil/v3/process"
+
+	"github.com/ksred/power-patrol/metrics"
+	"github.com/ksred/power-patrol/power"
+	"github.com/ksred/power-patrol/storage"
+)
+
+type ProcessUsage struct {
+	PID          int32
+	Name         string
+	CPUUsage     float64
+	MemUsage     float64
+	EnergyJoules float64
+	AvgWatts     float64
+	Timestamp    time.Time
+}
+
+// cpuSample remembers a process's cumulative CPU time at a point in
+// wall-clock time, so the next sample can turn it into a percentage and a
+// CPU-seconds delta for energy attribution.
+type cpuSample struct {
+	times *cpu.TimesStat
+	at    time.Time
+}
+
+// downsampleInterval is how often the run daemon rolls up and prunes the
+// store, independent of the sampling loop's own interval.
+const downsampleInterval = 1 * time.Minute
+
+var (
+	prevCPUTimes = map[int32]cpuSample{}
+	prevCPUMutex sync.Mutex
+
+	energySource   power.Source
+	energyAttrib   = power.NewAttributor()
+	energyWarnOnce sync.Once
+)
+
+func init() {
+	src, err := power.NewSource()
+	if err != nil {
+		energyWarnOnce.Do(func() {
+			log.Printf("Energy accounting disabled: %v\n", err)
+		})
+		return
+	}
+	energySource = src
+}
+
+// CollectorOptions bundles everything the sampling loop can optionally
+// report to: the store is required, metrics export and remote push are
+// only wired up when configured.
+type CollectorOptions struct {
+	Store          *storage.Store
+	Exporter       *metrics.Exporter
+	Pusher         *metrics.Pusher
+	Socket         *socketServer
+	PushInterval   time.Duration
+	SampleInterval time.Duration
+}
+
+// collectUsageBackground samples every running process on SampleInterval
+// (defaulting to 1s) and persists the results to Store, optionally
+// updating a Prometheus exporter and pushing to a remote collector.
+func collectUsageBackground(opts CollectorOptions) {
+	interval := opts.SampleInterval
+	if interval <= 0 {
+		interval = time.Second
+	}
+
+	lastDownsample := time.Now()
+	lastPush := time.Now()
+	pushInterval := opts.PushInterval
+	if pushInterval <= 0 {
+		pushInterval = 15 * time.Second
+	}
+
+	for {
+		batch, err := collectSnapshot(opts.Store)
+		if err != nil {
+			log.Printf("Error collecting process snapshot: %v\n", err)
+		}
+
+		if opts.Exporter != nil && batch != nil {
+			opts.Exporter.Update(toSamples(batch))
+		}
+
+		if opts.Socket != nil && batch != nil {
+			opts.Socket.Broadcast(toSamples(batch))
+		}
+
+		if now := time.Now(); opts.Pusher != nil && batch != nil && now.Sub(lastPush) >= pushInterval {
+			if err := opts.Pusher.Push(toSamples(batch)); err != nil {
+				log.Printf("Error pushing samples: %v\n", err)
+			}
+			lastPush = now
+		}
+
+		if now := time.Now(); now.Sub(lastDownsample) >= downsampleInterval {
+			if err := opts.Store.Downsample(now, retentionDuration()); err != nil {
+				log.Printf("Error downsampling store: %v\n", err)
+			}
+			lastDownsample = now
+		}
+
+		time.Sleep(interval)
+	}
+}
+
+func collectSnapshot(store *storage.Store) ([]ProcessUsage, error) {
+	batch, cpuSeconds, err := sampleProcesses()
+	if err != nil {
+		return nil, err
+	}
+
+	attributeEnergy(batch, cpuSeconds)
+
+	if err := store.InsertSamples(toSamples(batch)); err != nil {
+		return batch, err
+	}
+	return batch, nil
+}
+
+// sampleProcessesWithEnergy samples and attributes energy without
+// touching the store. It's used by `watch` when it can't attach to a
+// running `run` daemon and needs to collect its own samples in-process.
+func sampleProcessesWithEnergy() ([]ProcessUsage, error) {
+	batch, cpuSeconds, err := sampleProcesses()
+	if err != nil {
+		return nil, err
+	}
+	attributeEnergy(batch, cpuSeconds)
+	return batch, nil
+}
+
+// sampleProcesses takes one CPU/memory reading of every running process.
+// It's shared by collectSnapshot (which persists the result) and the
+// alert watchdog (which only needs the live numbers).
+func sampleProcesses() ([]ProcessUsage, map[int32]float64, error) {
+	procs, err := process.Processes()
+	if err != nil {
+		return nil, nil, fmt.Errorf("listing processes: %w", err)
+	}
+
+	numCPU, err := cpu.Counts(true)
+	if err != nil || numCPU == 0 {
+		numCPU = 1
+	}
+
+	now := time.Now()
+	seen := make(map[int32]struct{}, len(procs))
+	cpuSeconds := make(map[int32]float64, len(procs))
+	batch := make([]ProcessUsage, 0, len(procs))
+
+	for _, p := range procs {
+		times, err := p.Times()
+		if err != nil {
+			if err == process.ErrorProcessNotRunning {
+				continue
+			}
+			continue
+		}
+
+		name, err := p.Name()
+		if err != nil {
+			name = "unknown"
+		}
+
+		memPct, err := p.MemoryPercent()
+		if err != nil {
+			memPct = 0
+		}
+
+		seen[p.Pid] = struct{}{}
+		cpuPct, cpuDeltaSeconds := cpuPercentSince(p.Pid, times, now, numCPU)
+		cpuSeconds[p.Pid] = cpuDeltaSeconds
+
+		batch = append(batch, ProcessUsage{
+			PID:       p.Pid,
+			Name:      name,
+			CPUUsage:  cpuPct,
+			MemUsage:  float64(memPct),
+			Timestamp: now,
+		})
+	}
+
+	dropStalePIDs(seen)
+	return batch, cpuSeconds, nil
+}
+
+func toSamples(batch []ProcessUsage) []storage.Sample {
+	samples := make([]storage.Sample, len(batch))
+	for i, usage := range batch {
+		samples[i] = storage.Sample{
+			Timestamp:    usage.Timestamp,
+			PID:          usage.PID,
+			Name:         usage.Name,
+			CPUUsage:     usage.CPUUsage,
+			MemUsage:     usage.MemUsage,
+			EnergyJoules: usage.EnergyJoules,
+			AvgWatts:     usage.AvgWatts,
+		}
+	}
+	return samples
+}
+
+// attributeEnergy fills in EnergyJoules/AvgWatts on batch in place,
+// splitting the sampling window's package energy across processes
+// proportionally to the CPU-seconds each one consumed. It's a no-op if
+// this platform has no usable energy source.
+func attributeEnergy(batch []ProcessUsage, cpuSeconds map[int32]float64) {
+	if energySource == nil {
+		return
+	}
+
+	joules, elapsed, err := energySource.PackageEnergyJoules()
+	if err != nil {
+		log.Printf("Error reading package energy: %v\n", err)
+		return
+	}
+	if joules == 0 && elapsed == 0 {
+		return // first sample since startup; nothing to diff against yet
+	}
+
+	attributed := energyAttrib.Attribute(joules, elapsed, cpuSeconds)
+	for i := range batch {
+		if e, ok := attributed[batch[i].PID]; ok {
+			batch[i].EnergyJoules = e.Joules
+			batch[i].AvgWatts = e.Watts
+		}
+	}
+}
+
+// cpuPercentSince turns a process's cumulative CPU time into a percentage
+// of wall-clock time since the prior sample, scaled by the number of
+// logical CPUs so the figure lines up with what `top` reports. It also
+// returns the raw CPU-seconds delta for energy attribution. The first
+// sample for a PID has nothing to diff against and reports zero for both.
+func cpuPercentSince(pid int32, times *cpu.TimesStat, now time.Time, numCPU int) (percent float64, deltaSeconds float64) {
+	prevCPUMutex.Lock()
+	defer prevCPUMutex.Unlock()
+
+	prev, ok := prevCPUTimes[pid]
+	prevCPUTimes[pid] = cpuSample{times: times, at: now}
+	if !ok {
+		return 0, 0
+	}
+
+	wallDelta := now.Sub(prev.at).Seconds()
+	if wallDelta <= 0 {
+		return 0, 0
+	}
+
+	cpuDelta := times.Total() - prev.times.Total()
+	if cpuDelta < 0 {
+		return 0, 0
+	}
+
+	return (cpuDelta / (wallDelta * float64(numCPU))) * 100, cpuDelta
+}
+
+// dropStalePIDs forgets prior CPU samples for processes that no longer
+// appear in the latest snapshot, so the prev-times map doesn't grow
+// without bound as PIDs churn.
+func dropStalePIDs(seen map[int32]struct{}) {
+	prevCPUMutex.Lock()
+	defer prevCPUMutex.Unlock()
+	for pid := range prevCPUTimes {
+		if _, ok := seen[pid]; !ok {
+			delete(prevCPUTimes, pid)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"log"
+	"time"
+
+	"github.com/ksred/power-patrol/sandbox"
+)
+
+const (
+	configFilename   = "powerpatrol.json"
+	dbFilename       = "powerpatrol.db"
+	defaultRetention = 7 * 24 * time.Hour
+)
+
+type Config struct {
+	RetentionDuration string `json:"retention_duration"`
+
+	MetricsAddr    string `json:"metrics_addr"`
+	MetricsTopN    int    `json:"metrics_top_n"`
+	MetricsSortKey string `json:"metrics_sort_key"`
+
+	ReportingServerAddr   string `json:"reporting_server_addr"`
+	ReportingToken        string `json:"reporting_token"`
+	ReportingOrg          string `json:"reporting_org"`
+	ReportingIntervalSecs int    `json:"reporting_interval_secs"`
+
+	Sandbox sandbox.Config `json:"sandbox"`
+}
+
+var config Config
+
+func loadConfig() {
+	data, err := ioutil.ReadFile(configPath)
+	if err != nil {
+		log.Printf("Error reading config file: %v\n", err)
+		config.RetentionDuration = defaultRetention.String()
+		saveConfig()
+		return
+	}
+
+	err = json.Unmarshal(data, &config)
+	if err != nil {
+		log.Printf("Error parsing config file: %v\n", err)
+		config.RetentionDuration = defaultRetention.String()
+		saveConfig()
+	}
+}
+
+func saveConfig() {
+	data, err := json.Marshal(config)
+	if err != nil {
+		log.Printf("Error encoding config: %v\n", err)
+		return
+	}
+
+	err = ioutil.WriteFile(configPath, data, 0644)
+	if err != nil {
+		log.Printf("Error writing config file: %v\n", err)
+	}
+}
+
+// retentionDuration parses config.RetentionDuration, falling back to
+// defaultRetention if it's unset or malformed.
+func retentionDuration() time.Duration {
+	if config.RetentionDuration == "" {
+		return defaultRetention
+	}
+	d, err := time.ParseDuration(config.RetentionDuration)
+	if err != nil {
+		log.Printf("Invalid retention_duration %q, using default: %v\n", config.RetentionDuration, err)
+		return defaultRetention
+	}
+	return d
+}
+
+// reportingInterval parses config.ReportingIntervalSecs, falling back to
+// a sane default when unset.
+func reportingInterval() time.Duration {
+	if config.ReportingIntervalSecs <= 0 {
+		return 15 * time.Second
+	}
+	return time.Duration(config.ReportingIntervalSecs) * time.Second
+}
@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"sync"
+
+	"github.com/ksred/power-patrol/storage"
+)
+
+// socketPath is where `run` listens for `watch` clients that want a live
+// feed without reading the store. It lives in a fixed, well-known
+// location so watch doesn't need to be told where the daemon is.
+const socketPath = "/tmp/powerpatrol.sock"
+
+// socketServer fans each sampling batch out to every connected `watch`
+// client over a Unix socket, as newline-delimited JSON.
+type socketServer struct {
+	mu      sync.Mutex
+	clients map[net.Conn]struct{}
+}
+
+func newSocketServer() *socketServer {
+	return &socketServer{clients: map[net.Conn]struct{}{}}
+}
+
+// Serve starts accepting connections at path in the background. It
+// removes any stale socket file left behind by a prior, uncleanly
+// stopped daemon before listening, and restricts the socket to the
+// daemon's own user: samples broadcast over it include every process's
+// name, CPU%, memory%, and energy use on the host, which other local
+// users have no business reading.
+func (s *socketServer) Serve(path string) error {
+	os.Remove(path)
+
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		return err
+	}
+	if err := os.Chmod(path, 0600); err != nil {
+		ln.Close()
+		return fmt.Errorf("restricting watch socket permissions: %w", err)
+	}
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				log.Printf("Error accepting watch client: %v\n", err)
+				return
+			}
+			s.mu.Lock()
+			s.clients[conn] = struct{}{}
+			s.mu.Unlock()
+		}
+	}()
+
+	return nil
+}
+
+// Broadcast sends samples to every connected client, dropping any client
+// whose connection has gone bad.
+func (s *socketServer) Broadcast(samples []storage.Sample) {
+	data, err := json.Marshal(samples)
+	if err != nil {
+		log.Printf("Error encoding samples for watch clients: %v\n", err)
+		return
+	}
+	data = append(data, '\n')
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for conn := range s.clients {
+		if _, err := conn.Write(data); err != nil {
+			conn.Close()
+			delete(s.clients, conn)
+		}
+	}
+}
@@ -0,0 +1,27 @@
+// Package cmd implements the powerpatrol CLI: run (collect), top (report),
+// watch (live refresh), and alert (threshold watchdog) subcommands.
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var configPath string
+
+var rootCmd = &cobra.Command{
+	Use:   "powerpatrol",
+	Short: "Track per-process CPU, memory, and power usage",
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		loadConfig()
+	},
+}
+
+func init() {
+	rootCmd.PersistentFlags().StringVar(&configPath, "config", configFilename, "path to config file")
+}
+
+// Execute runs the root command with os.Args, dispatching to whichever
+// subcommand was requested.
+func Execute() error {
+	return rootCmd.Execute()
+}
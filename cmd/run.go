@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ksred/power-patrol/metrics"
+	"github.com/ksred/power-patrol/sandbox"
+	"github.com/ksred/power-patrol/storage"
+)
+
+var (
+	runInterval    time.Duration
+	runMetricsAddr string
+)
+
+var runCmd = &cobra.Command{
+	Use:   "run",
+	Short: "Collect process usage samples into the on-disk store",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		store, err := storage.Open(dbFilename)
+		if err != nil {
+			return fmt.Errorf("opening store: %w", err)
+		}
+		defer store.Close()
+
+		socket := newSocketServer()
+		if err := socket.Serve(socketPath); err != nil {
+			log.Printf("Error serving watch socket at %s: %v\n", socketPath, err)
+		}
+
+		opts := CollectorOptions{
+			Store:          store,
+			Exporter:       metrics.NewExporter(config.MetricsTopN, config.MetricsSortKey),
+			Socket:         socket,
+			PushInterval:   reportingInterval(),
+			SampleInterval: runInterval,
+		}
+
+		metricsAddr := runMetricsAddr
+		if metricsAddr == "" {
+			metricsAddr = config.MetricsAddr
+		}
+		if metricsAddr != "" {
+			serveMetrics(metricsAddr, opts.Exporter)
+		}
+
+		if config.ReportingServerAddr != "" {
+			opts.Pusher = metrics.NewPusher(metrics.PushConfig{
+				ServerAddr: config.ReportingServerAddr,
+				Token:      config.ReportingToken,
+				Org:        config.ReportingOrg,
+			})
+		}
+
+		// Apply resource limits, the seccomp filter, and privilege drop
+		// now that the config file, the store, and any metrics listener
+		// are already open -- Sandbox.Apply no-ops the pieces that weren't
+		// configured.
+		if err := sandbox.Apply(config.Sandbox); err != nil {
+			return fmt.Errorf("sandboxing collector: %w", err)
+		}
+
+		collectUsageBackground(opts)
+		return nil
+	},
+}
+
+func init() {
+	runCmd.Flags().DurationVar(&runInterval, "interval", time.Second, "sampling interval")
+	runCmd.Flags().StringVar(&runMetricsAddr, "metrics-addr", "", "address to serve Prometheus /metrics on (e.g. :9090)")
+	rootCmd.AddCommand(runCmd)
+}
+
+// serveMetrics starts the Prometheus /metrics endpoint in the background.
+// It doesn't block startup and logs rather than exits if the listener
+// fails, since metrics export is a secondary feature of the daemon.
+func serveMetrics(addr string, exporter *metrics.Exporter) {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", exporter.Handler())
+
+	go func() {
+		if err := http.ListenAndServe(addr, mux); err != nil {
+			log.Printf("Error serving metrics on %s: %v\n", addr, err)
+		}
+	}()
+}
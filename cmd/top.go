@@ -0,0 +1,129 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ksred/power-patrol/storage"
+)
+
+var (
+	topDuration time.Duration
+	topSort     string
+	topLimit    int
+	topFormat   string
+)
+
+var topCmd = &cobra.Command{
+	Use:   "top",
+	Short: "Show the top processes by usage over a recent window",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		store, err := storage.OpenReadOnly(dbFilename)
+		if err != nil {
+			return fmt.Errorf("opening store: %w", err)
+		}
+		defer store.Close()
+
+		return displayTopProcesses(store, topDuration, topSort, topLimit, topFormat)
+	},
+}
+
+func init() {
+	topCmd.Flags().DurationVar(&topDuration, "duration", 5*time.Minute, "how far back to look")
+	topCmd.Flags().StringVar(&topSort, "sort", "cpu", "sort key: cpu|mem|energy")
+	topCmd.Flags().IntVar(&topLimit, "limit", 10, "number of processes to show")
+	topCmd.Flags().StringVar(&topFormat, "format", "table", "output format: table|json|csv")
+	rootCmd.AddCommand(topCmd)
+}
+
+func displayTopProcesses(store *storage.Store, duration time.Duration, sortBy string, limit int, format string) error {
+	startTime := time.Now().Add(-duration)
+
+	samples, err := store.Query(startTime, time.Now())
+	if err != nil {
+		return fmt.Errorf("querying store: %w", err)
+	}
+
+	data := make([]ProcessUsage, len(samples))
+	for i, sample := range samples {
+		data[i] = ProcessUsage{
+			PID:          sample.PID,
+			Name:         sample.Name,
+			CPUUsage:     sample.CPUUsage,
+			MemUsage:     sample.MemUsage,
+			EnergyJoules: sample.EnergyJoules,
+			AvgWatts:     sample.AvgWatts,
+			Timestamp:    sample.Timestamp,
+		}
+	}
+
+	sort.Slice(data, func(i, j int) bool {
+		switch sortBy {
+		case "mem":
+			return data[i].MemUsage > data[j].MemUsage
+		case "energy":
+			return data[i].EnergyJoules > data[j].EnergyJoules
+		default:
+			return data[i].CPUUsage > data[j].CPUUsage
+		}
+	})
+
+	if limit > 0 && len(data) > limit {
+		data = data[:limit]
+	}
+
+	switch format {
+	case "json":
+		return printTopJSON(data)
+	case "csv":
+		return printTopCSV(data)
+	default:
+		printTopTable(data)
+		return nil
+	}
+}
+
+func printTopTable(data []ProcessUsage) {
+	fmt.Println("\033[1mTop Processes by Power Usage:\033[0m")
+	fmt.Printf("\033[1m%-30s %-10s %-10s %-10s %-10s\033[0m\n", "Process", "CPU", "Memory", "Watts", "Joules")
+
+	for _, usage := range data {
+		fmt.Printf("%-30s %-9.2f %-9.2f %-9.2f %-9.2f\n", usage.Name, usage.CPUUsage, usage.MemUsage, usage.AvgWatts, usage.EnergyJoules)
+	}
+}
+
+func printTopJSON(data []ProcessUsage) error {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(data)
+}
+
+func printTopCSV(data []ProcessUsage) error {
+	w := csv.NewWriter(os.Stdout)
+	defer w.Flush()
+
+	if err := w.Write([]string{"pid", "name", "cpu_usage", "mem_usage", "avg_watts", "energy_joules"}); err != nil {
+		return err
+	}
+	for _, usage := range data {
+		row := []string{
+			strconv.Itoa(int(usage.PID)),
+			usage.Name,
+			strconv.FormatFloat(usage.CPUUsage, 'f', 2, 64),
+			strconv.FormatFloat(usage.MemUsage, 'f', 2, 64),
+			strconv.FormatFloat(usage.AvgWatts, 'f', 2, 64),
+			strconv.FormatFloat(usage.EnergyJoules, 'f', 2, 64),
+		}
+		if err := w.Write(row); err != nil {
+			return err
+		}
+	}
+	return w.Error()
+}
@@ -0,0 +1,165 @@
+package cmd
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ksred/power-patrol/storage"
+	"github.com/ksred/power-patrol/tui"
+)
+
+var watchInterval time.Duration
+
+var watchCmd = &cobra.Command{
+	Use:   "watch",
+	Short: "Live interactive process table, like top(1)",
+	Long: `watch shows a live, sortable, filterable table of process usage.
+
+Unlike top, which queries the on-disk store for a past window, watch
+reads live from the same collector loop via a channel, so no daemon is
+required -- it either attaches to a running "run" daemon's socket, or
+spins up its own in-process collector if none is found.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		samples, stop, err := attachSamples(watchInterval)
+		if err != nil {
+			return fmt.Errorf("starting sample feed: %w", err)
+		}
+		defer stop()
+
+		history, closeHistory, err := openHistory(dbFilename)
+		if err != nil {
+			return fmt.Errorf("opening history store: %w", err)
+		}
+		defer closeHistory()
+
+		return tui.Run(tui.Options{
+			Samples: samples,
+			History: history,
+			Kill:    killProcess,
+		})
+	},
+}
+
+func init() {
+	watchCmd.Flags().DurationVar(&watchInterval, "interval", time.Second, "sampling interval when no run daemon is available")
+	rootCmd.AddCommand(watchCmd)
+}
+
+// attachSamples returns a channel of sample batches and a cleanup
+// function. It first tries to attach to a running `run` daemon over its
+// Unix socket; if nothing is listening, it falls back to sampling
+// processes itself on interval.
+func attachSamples(interval time.Duration) (<-chan []storage.Sample, func(), error) {
+	conn, err := net.DialTimeout("unix", socketPath, 200*time.Millisecond)
+	if err == nil {
+		ch := make(chan []storage.Sample)
+		go streamFromSocket(conn, ch)
+		return ch, func() { conn.Close() }, nil
+	}
+
+	ch := make(chan []storage.Sample)
+	done := make(chan struct{})
+	go collectStandalone(interval, ch, done)
+	return ch, func() { close(done) }, nil
+}
+
+// streamFromSocket decodes newline-delimited JSON sample batches off conn
+// and forwards them to ch until the connection closes.
+func streamFromSocket(conn net.Conn, ch chan<- []storage.Sample) {
+	defer close(ch)
+	scanner := bufio.NewScanner(conn)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var samples []storage.Sample
+		if err := json.Unmarshal(scanner.Bytes(), &samples); err != nil {
+			continue
+		}
+		ch <- samples
+	}
+}
+
+// collectStandalone samples processes itself on interval, for when no
+// run daemon is available to attach to.
+func collectStandalone(interval time.Duration, ch chan<- []storage.Sample, done <-chan struct{}) {
+	defer close(ch)
+	if interval <= 0 {
+		interval = time.Second
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			batch, err := sampleProcessesWithEnergy()
+			if err != nil {
+				continue
+			}
+			select {
+			case ch <- toSamples(batch):
+			case <-done:
+				return
+			}
+		}
+	}
+}
+
+// openHistory opens the on-disk store at path once and returns a
+// HistoryFunc backed by that single connection, plus a cleanup function
+// to close it. bubbletea calls HistoryFunc once per visible row on every
+// redraw, so it must reuse a handle rather than opening and closing one
+// per call. If the store doesn't exist yet -- no run daemon has ever
+// started -- the returned HistoryFunc is nil and watch's sparkline
+// column is simply disabled instead of erroring.
+func openHistory(path string) (tui.HistoryFunc, func(), error) {
+	noop := func() {}
+
+	if _, err := os.Stat(path); err != nil {
+		return nil, noop, nil
+	}
+
+	store, err := storage.OpenReadOnly(path)
+	if err != nil {
+		return nil, noop, err
+	}
+
+	fn := func(pid int32, window time.Duration) ([]storage.Sample, error) {
+		samples, err := store.Query(time.Now().Add(-window), time.Now())
+		if err != nil {
+			return nil, err
+		}
+
+		filtered := make([]storage.Sample, 0, len(samples))
+		for _, s := range samples {
+			if s.PID == pid {
+				filtered = append(filtered, s)
+			}
+		}
+		return filtered, nil
+	}
+	return fn, func() { store.Close() }, nil
+}
+
+// killProcess signals pid, using SIGKILL instead of SIGTERM when force
+// is set.
+func killProcess(pid int32, force bool) error {
+	proc, err := os.FindProcess(int(pid))
+	if err != nil {
+		return err
+	}
+
+	sig := syscall.SIGTERM
+	if force {
+		sig = syscall.SIGKILL
+	}
+	return proc.Signal(sig)
+}
@@ -0,0 +1,111 @@
+// Package metrics exposes process usage samples as Prometheus gauges and,
+// optionally, pushes the same series to a remote time-series collector.
+package metrics
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"github.com/ksred/power-patrol/storage"
+)
+
+// Exporter serves the most recent sample batch as Prometheus gauges. Only
+// the top N processes by sortKey are exported per update, so a host
+// churning through thousands of short-lived PIDs doesn't produce a
+// series per PID.
+type Exporter struct {
+	registry *prometheus.Registry
+
+	cpuGauge   *prometheus.GaugeVec
+	memGauge   *prometheus.GaugeVec
+	wattsGauge *prometheus.GaugeVec
+
+	topN    int
+	sortKey string
+}
+
+// NewExporter builds an Exporter that retains gauges for at most topN
+// processes per Update call, ranked by sortKey ("cpu", "mem", or
+// "energy" -- matching top and watch's --sort/c-m-e conventions). An
+// unrecognized or empty sortKey ranks by CPU.
+func NewExporter(topN int, sortKey string) *Exporter {
+	registry := prometheus.NewRegistry()
+
+	labels := []string{"pid", "name"}
+	cpuGauge := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "powerpatrol_process_cpu_percent",
+		Help: "Per-process CPU usage percentage.",
+	}, labels)
+	memGauge := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "powerpatrol_process_mem_percent",
+		Help: "Per-process memory usage percentage.",
+	}, labels)
+	wattsGauge := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "powerpatrol_process_watts",
+		Help: "Per-process estimated average power draw in watts.",
+	}, labels)
+
+	registry.MustRegister(cpuGauge, memGauge, wattsGauge)
+
+	if topN <= 0 {
+		topN = 20
+	}
+
+	return &Exporter{
+		registry:   registry,
+		cpuGauge:   cpuGauge,
+		memGauge:   memGauge,
+		wattsGauge: wattsGauge,
+		topN:       topN,
+		sortKey:    sortKey,
+	}
+}
+
+// Handler returns the http.Handler to serve at /metrics.
+func (e *Exporter) Handler() http.Handler {
+	return promhttp.HandlerFor(e.registry, promhttp.HandlerOpts{})
+}
+
+// Update replaces the exported gauges with the top N samples, ranked by
+// e.sortKey, from the latest batch.
+func (e *Exporter) Update(samples []storage.Sample) {
+	e.cpuGauge.Reset()
+	e.memGauge.Reset()
+	e.wattsGauge.Reset()
+
+	top := topBySortKey(samples, e.topN, e.sortKey)
+	for _, s := range top {
+		labels := prometheus.Labels{
+			"pid":  strconv.Itoa(int(s.PID)),
+			"name": s.Name,
+		}
+		e.cpuGauge.With(labels).Set(s.CPUUsage)
+		e.memGauge.With(labels).Set(s.MemUsage)
+		e.wattsGauge.With(labels).Set(s.AvgWatts)
+	}
+}
+
+func topBySortKey(samples []storage.Sample, n int, sortKey string) []storage.Sample {
+	sorted := make([]storage.Sample, len(samples))
+	copy(sorted, samples)
+
+	sort.Slice(sorted, func(i, j int) bool {
+		switch sortKey {
+		case "mem":
+			return sorted[i].MemUsage > sorted[j].MemUsage
+		case "energy":
+			return sorted[i].EnergyJoules > sorted[j].EnergyJoules
+		default:
+			return sorted[i].CPUUsage > sorted[j].CPUUsage
+		}
+	})
+
+	if len(sorted) > n {
+		sorted = sorted[:n]
+	}
+	return sorted
+}
@@ -0,0 +1,29 @@
+package metrics
+
+import (
+	"testing"
+
+	"github.com/ksred/power-patrol/storage"
+)
+
+// TestTopBySortKeyRanksByConfiguredKey checks the cardinality guard cuts
+// the top N by whichever sort key is configured, not always CPU --
+// otherwise a memory- or energy-bound host could have exactly the
+// processes an operator cares about excluded from export.
+func TestTopBySortKeyRanksByConfiguredKey(t *testing.T) {
+	samples := []storage.Sample{
+		{PID: 1, Name: "low-everything", CPUUsage: 1, MemUsage: 1, EnergyJoules: 1},
+		{PID: 2, Name: "high-mem", CPUUsage: 1, MemUsage: 90, EnergyJoules: 1},
+		{PID: 3, Name: "high-cpu", CPUUsage: 90, MemUsage: 1, EnergyJoules: 1},
+	}
+
+	top := topBySortKey(samples, 1, "mem")
+	if len(top) != 1 || top[0].Name != "high-mem" {
+		t.Fatalf("topBySortKey(..., %q) = %v, want [high-mem]", "mem", top)
+	}
+
+	top = topBySortKey(samples, 1, "cpu")
+	if len(top) != 1 || top[0].Name != "high-cpu" {
+		t.Fatalf("topBySortKey(..., %q) = %v, want [high-cpu]", "cpu", top)
+	}
+}
@@ -0,0 +1,79 @@
+package metrics
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ksred/power-patrol/storage"
+)
+
+// PushConfig configures periodic delivery of samples to a remote
+// InfluxDB v2 (or InfluxDB-line-protocol-compatible) collector.
+type PushConfig struct {
+	ServerAddr string
+	Token      string
+	Org        string
+	Bucket     string
+}
+
+// Pusher sends sample batches to a remote collector as InfluxDB v2 line
+// protocol writes. It's optional: callers only construct one when
+// ReportingServerAddr is configured.
+type Pusher struct {
+	cfg    PushConfig
+	client *http.Client
+}
+
+// NewPusher builds a Pusher for cfg. Bucket defaults to "powerpatrol" if
+// unset.
+func NewPusher(cfg PushConfig) *Pusher {
+	if cfg.Bucket == "" {
+		cfg.Bucket = "powerpatrol"
+	}
+	return &Pusher{
+		cfg:    cfg,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Push writes samples to the configured InfluxDB v2 endpoint as a single
+// line-protocol batch.
+func (p *Pusher) Push(samples []storage.Sample) error {
+	if len(samples) == 0 {
+		return nil
+	}
+
+	var body strings.Builder
+	for _, s := range samples {
+		fmt.Fprintf(&body, "powerpatrol_process,pid=%d,name=%s cpu_percent=%f,mem_percent=%f,watts=%f %d\n",
+			s.PID, escapeTag(s.Name), s.CPUUsage, s.MemUsage, s.AvgWatts, s.Timestamp.UnixNano())
+	}
+
+	url := fmt.Sprintf("%s/api/v2/write?org=%s&bucket=%s&precision=ns", p.cfg.ServerAddr, p.cfg.Org, p.cfg.Bucket)
+	req, err := http.NewRequest(http.MethodPost, url, strings.NewReader(body.String()))
+	if err != nil {
+		return fmt.Errorf("building push request: %w", err)
+	}
+	req.Header.Set("Authorization", "Token "+p.cfg.Token)
+	req.Header.Set("Content-Type", "text/plain; charset=utf-8")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("pushing samples: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("push rejected with status %s", resp.Status)
+	}
+	return nil
+}
+
+// escapeTag escapes the characters InfluxDB line protocol treats as tag
+// delimiters: commas, spaces, and equals signs.
+func escapeTag(value string) string {
+	replacer := strings.NewReplacer(",", `\,`, " ", `\ `, "=", `\=`)
+	return replacer.Replace(value)
+}
@@ -0,0 +1,79 @@
+// Package power estimates per-process energy draw. It combines a
+// platform-specific reading of total package (or battery) energy for a
+// sampling window with each process's share of CPU time in that window,
+// since no common OS API attributes watts to a PID directly.
+package power
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrUnsupported is returned by a Source when the host has no known way
+// to read package or battery energy (e.g. a VM with no RAPL counters and
+// no battery).
+var ErrUnsupported = errors.New("power: no energy source available on this platform")
+
+// Source reports energy consumed by the whole machine (CPU package, or
+// battery discharge as a fallback) since the previous call.
+type Source interface {
+	// PackageEnergyJoules returns the joules consumed and the wall-clock
+	// duration the reading covers. The first call after NewSource has
+	// nothing to diff against and returns zero values.
+	PackageEnergyJoules() (joules float64, elapsed time.Duration, err error)
+}
+
+// NewSource builds the best Source available on the current platform. It
+// returns ErrUnsupported if none of the known mechanisms (Intel/AMD RAPL,
+// powermetrics, battery power_now) are usable.
+func NewSource() (Source, error) {
+	return newPlatformSource()
+}
+
+// ProcessEnergy is one process's share of a sampling window's package
+// energy.
+type ProcessEnergy struct {
+	Joules float64
+	Watts  float64
+}
+
+// Attributor splits a window's total package energy across processes
+// proportionally to how much CPU time each one consumed in that window.
+type Attributor struct{}
+
+// NewAttributor returns an Attributor. It holds no state; it's a type for
+// symmetry with Source and room to grow (e.g. per-core weighting).
+func NewAttributor() *Attributor {
+	return &Attributor{}
+}
+
+// Attribute splits totalJoules across the processes in cpuSeconds, a map
+// of PID to CPU-seconds consumed during elapsed. Processes are weighted by
+// their share of total CPU-seconds; a window with no CPU activity at all
+// attributes nothing (there's no sane way to split idle-system energy by
+// process).
+func (a *Attributor) Attribute(totalJoules float64, elapsed time.Duration, cpuSeconds map[int32]float64) map[int32]ProcessEnergy {
+	result := make(map[int32]ProcessEnergy, len(cpuSeconds))
+
+	var totalCPUSeconds float64
+	for _, s := range cpuSeconds {
+		totalCPUSeconds += s
+	}
+	if totalCPUSeconds <= 0 || totalJoules <= 0 {
+		return result
+	}
+
+	elapsedSeconds := elapsed.Seconds()
+
+	for pid, s := range cpuSeconds {
+		share := s / totalCPUSeconds
+		joules := totalJoules * share
+		watts := 0.0
+		if elapsedSeconds > 0 {
+			watts = joules / elapsedSeconds
+		}
+		result[pid] = ProcessEnergy{Joules: joules, Watts: watts}
+	}
+
+	return result
+}
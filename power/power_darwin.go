@@ -0,0 +1,60 @@
+//go:build darwin
+
+package power
+
+import (
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+// combinedPowerRE matches powermetrics' "Combined Power (CPU + GPU + ANE): N mW"
+// summary line, which is present across the macOS versions we support.
+var combinedPowerRE = regexp.MustCompile(`Combined Power \(CPU \+ GPU \+ ANE\):\s*([\d.]+)\s*mW`)
+
+// darwinSource shells out to powermetrics, which requires root (or the
+// com.apple.private.iokit.powerdesc entitlement) to read the IOReport
+// power sensors. One-shot invocations are used per sample rather than a
+// long-running subscription so a missing/old powermetrics binary only
+// fails a single window instead of the whole collector.
+type darwinSource struct {
+	prevAt time.Time
+}
+
+func newPlatformSource() (Source, error) {
+	if _, err := exec.LookPath("powermetrics"); err != nil {
+		return nil, ErrUnsupported
+	}
+	return &darwinSource{}, nil
+}
+
+func (s *darwinSource) PackageEnergyJoules() (float64, time.Duration, error) {
+	now := time.Now()
+	firstSample := s.prevAt.IsZero()
+	elapsed := now.Sub(s.prevAt)
+	s.prevAt = now
+
+	out, err := exec.Command("powermetrics", "--samplers", "cpu_power,gpu_power", "-i", "1000", "-n", "1").Output()
+	if err != nil {
+		return 0, 0, fmt.Errorf("running powermetrics: %w", err)
+	}
+
+	matches := combinedPowerRE.FindSubmatch(out)
+	if matches == nil {
+		return 0, 0, fmt.Errorf("powermetrics output missing combined power reading")
+	}
+
+	milliwatts, err := strconv.ParseFloat(string(matches[1]), 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("parsing powermetrics power reading: %w", err)
+	}
+
+	if firstSample {
+		return 0, 0, nil
+	}
+
+	watts := milliwatts / 1000
+	return watts * elapsed.Seconds(), elapsed, nil
+}
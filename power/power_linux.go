@@ -0,0 +1,147 @@
+//go:build linux
+
+package power
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	raplGlob          = "/sys/class/powercap/intel-rapl:*/energy_uj"
+	batteryGlob       = "/sys/class/power_supply/BAT*/power_now"
+	raplMaxEnergyFile = "max_energy_range_uj" // sibling of energy_uj within each zone directory
+)
+
+// linuxSource reads cumulative package energy from the RAPL powercap
+// interface (Intel and AMD's rapl-compatible zones both expose the same
+// energy_uj sysfs layout). If no RAPL zones exist it falls back to the
+// battery's instantaneous power_now, which isn't cumulative so it's
+// treated as an average over the sampling window instead of a delta.
+type linuxSource struct {
+	raplPaths  []string
+	prevEnergy map[string]uint64 // microjoules, per zone path
+	maxEnergy  map[string]uint64 // microjoules, per zone path; 0 if unknown
+	prevAt     time.Time
+
+	batteryPath string
+}
+
+func newPlatformSource() (Source, error) {
+	raplPaths, _ := filepath.Glob(raplGlob)
+	if len(raplPaths) > 0 {
+		return &linuxSource{raplPaths: raplPaths, prevEnergy: map[string]uint64{}, maxEnergy: map[string]uint64{}}, nil
+	}
+
+	batteryPaths, _ := filepath.Glob(batteryGlob)
+	if len(batteryPaths) > 0 {
+		return &linuxSource{batteryPath: batteryPaths[0]}, nil
+	}
+
+	return nil, ErrUnsupported
+}
+
+func (s *linuxSource) PackageEnergyJoules() (float64, time.Duration, error) {
+	now := time.Now()
+	firstSample := s.prevAt.IsZero()
+
+	var joules float64
+	var elapsed time.Duration
+	var err error
+	if len(s.raplPaths) > 0 {
+		joules, elapsed, err = s.raplEnergyJoules(now)
+	} else {
+		joules, elapsed, err = s.batteryEnergyJoules(now)
+	}
+	if firstSample {
+		return 0, 0, err
+	}
+	return joules, elapsed, err
+}
+
+func (s *linuxSource) raplEnergyJoules(now time.Time) (float64, time.Duration, error) {
+	var totalMicrojoules uint64
+	for _, path := range s.raplPaths {
+		raw, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		value, err := strconv.ParseUint(strings.TrimSpace(string(raw)), 10, 64)
+		if err != nil {
+			continue
+		}
+
+		prev, ok := s.prevEnergy[path]
+		s.prevEnergy[path] = value
+		if !ok {
+			continue
+		}
+
+		delta := value
+		if value >= prev {
+			delta = value - prev
+		} else if max := s.zoneMaxEnergy(path); max > 0 {
+			// Counter wrapped past its hardware range; the zone kept
+			// counting from 0, so the delta is what's left until the old
+			// value would have wrapped, plus what's accumulated since.
+			delta = (max - prev) + value
+		} else {
+			// Wrapped, but this zone doesn't expose max_energy_range_uj,
+			// so the wrap point is unknown; drop the zone for this window
+			// rather than report a bogus spike.
+			continue
+		}
+		totalMicrojoules += delta
+	}
+
+	elapsed := now.Sub(s.prevAt)
+	s.prevAt = now
+	if elapsed <= 0 {
+		return 0, 0, nil
+	}
+
+	return float64(totalMicrojoules) / 1e6, elapsed, nil
+}
+
+// zoneMaxEnergy returns the RAPL zone at path's max_energy_range_uj --
+// the value energy_uj wraps back to 0 from -- caching it after the
+// first read since it's a static hardware property. Returns 0 if the
+// sibling file is missing or unreadable, so callers can tell "unknown"
+// from a (nonsensical) zero-width range.
+func (s *linuxSource) zoneMaxEnergy(path string) uint64 {
+	if max, ok := s.maxEnergy[path]; ok {
+		return max
+	}
+
+	raw, err := os.ReadFile(filepath.Join(filepath.Dir(path), raplMaxEnergyFile))
+	var max uint64
+	if err == nil {
+		max, _ = strconv.ParseUint(strings.TrimSpace(string(raw)), 10, 64)
+	}
+	s.maxEnergy[path] = max
+	return max
+}
+
+func (s *linuxSource) batteryEnergyJoules(now time.Time) (float64, time.Duration, error) {
+	raw, err := os.ReadFile(s.batteryPath)
+	if err != nil {
+		return 0, 0, fmt.Errorf("reading %s: %w", s.batteryPath, err)
+	}
+	microwatts, err := strconv.ParseUint(strings.TrimSpace(string(raw)), 10, 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("parsing %s: %w", s.batteryPath, err)
+	}
+
+	elapsed := now.Sub(s.prevAt)
+	s.prevAt = now
+	if elapsed <= 0 {
+		return 0, 0, nil
+	}
+
+	watts := float64(microwatts) / 1e6
+	return watts * elapsed.Seconds(), elapsed, nil
+}
@@ -0,0 +1,55 @@
+//go:build linux
+
+package power
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func writeZone(t *testing.T, dir string, energy, max uint64) string {
+	t.Helper()
+	energyPath := filepath.Join(dir, "energy_uj")
+	if err := os.WriteFile(energyPath, []byte(strconv.FormatUint(energy, 10)), 0644); err != nil {
+		t.Fatalf("writing energy_uj: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, raplMaxEnergyFile), []byte(strconv.FormatUint(max, 10)), 0644); err != nil {
+		t.Fatalf("writing %s: %v", raplMaxEnergyFile, err)
+	}
+	return energyPath
+}
+
+// TestRaplEnergyJoulesHandlesWraparound checks that when a zone's counter
+// wraps (the new reading is lower than the previous one), the delta is
+// recovered using the zone's max_energy_range_uj instead of the window
+// being dropped.
+func TestRaplEnergyJoulesHandlesWraparound(t *testing.T) {
+	dir := t.TempDir()
+	const max = 1000
+	path := writeZone(t, dir, 100, max) // will be overwritten below to simulate a wrap
+
+	s := &linuxSource{
+		raplPaths:  []string{path},
+		prevEnergy: map[string]uint64{path: 900}, // previous reading, close to max
+		maxEnergy:  map[string]uint64{},
+		prevAt:     time.Now().Add(-time.Second),
+	}
+
+	joules, elapsed, err := s.raplEnergyJoules(time.Now())
+	if err != nil {
+		t.Fatalf("raplEnergyJoules() error = %v", err)
+	}
+	if elapsed <= 0 {
+		t.Fatalf("raplEnergyJoules() elapsed = %v, want > 0", elapsed)
+	}
+
+	// Wrapped from 900 toward max(1000) then up to 100: delta is
+	// (1000-900)+100 = 200 microjoules = 0.0002 joules.
+	wantJoules := float64(200) / 1e6
+	if joules != wantJoules {
+		t.Errorf("raplEnergyJoules() joules = %v, want %v", joules, wantJoules)
+	}
+}
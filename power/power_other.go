@@ -0,0 +1,7 @@
+//go:build !linux && !darwin
+
+package power
+
+func newPlatformSource() (Source, error) {
+	return nil, ErrUnsupported
+}
@@ -0,0 +1,9 @@
+//go:build !linux && !darwin
+
+package sandbox
+
+import "errors"
+
+func dropPrivileges(username string) error {
+	return errors.New("sandbox: dropping privileges is not supported on this platform")
+}
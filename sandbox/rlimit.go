@@ -0,0 +1,30 @@
+//go:build linux || darwin
+
+package sandbox
+
+import "golang.org/x/sys/unix"
+
+// setResourceLimits caps the process's address space and cumulative CPU
+// time so a parser bug chewing through a hostile /proc entry can't take
+// the whole host down with it. A zero value in Config leaves that limit
+// untouched.
+//
+// Scoped to linux/darwin rather than the broader "unix" tag: unix.Rlimit's
+// Cur/Max fields are uint64 on Linux but int64 on the BSDs, so the
+// uint64 Config fields below only assign cleanly on the platforms this
+// daemon actually ships on.
+func setResourceLimits(cfg Config) error {
+	if cfg.MaxMemoryBytes > 0 {
+		limit := unix.Rlimit{Cur: cfg.MaxMemoryBytes, Max: cfg.MaxMemoryBytes}
+		if err := unix.Setrlimit(unix.RLIMIT_AS, &limit); err != nil {
+			return err
+		}
+	}
+	if cfg.MaxCPUSeconds > 0 {
+		limit := unix.Rlimit{Cur: cfg.MaxCPUSeconds, Max: cfg.MaxCPUSeconds}
+		if err := unix.Setrlimit(unix.RLIMIT_CPU, &limit); err != nil {
+			return err
+		}
+	}
+	return nil
+}
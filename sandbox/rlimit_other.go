@@ -0,0 +1,11 @@
+//go:build !linux && !darwin
+
+package sandbox
+
+// setResourceLimits is a no-op outside linux/darwin: the BSDs declare
+// unix.Rlimit's fields as int64 rather than Linux's uint64 (see
+// rlimit.go), and Windows has no rlimit equivalent at all, so
+// Config.MaxMemoryBytes and MaxCPUSeconds are silently unenforced here.
+func setResourceLimits(cfg Config) error {
+	return nil
+}
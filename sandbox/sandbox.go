@@ -0,0 +1,53 @@
+// Package sandbox hardens the collector daemon: it enforces memory/CPU
+// rlimits, installs a seccomp-bpf syscall allowlist on Linux, and drops
+// root privileges to an unprivileged user once the process no longer
+// needs them. A long-running process that walks /proc is a liability if
+// it's ever exploited by a hostile entry there, so none of this is
+// optional cleanup -- it's the blast-radius limit for that case.
+package sandbox
+
+import (
+	"fmt"
+	"os"
+)
+
+// Config configures the sandbox. All fields are optional; a zero Config
+// enforces no limits, installs no seccomp filter, and drops no
+// privileges.
+type Config struct {
+	User           string `json:"user"`
+	MaxMemoryBytes uint64 `json:"max_memory_bytes"`
+	MaxCPUSeconds  uint64 `json:"max_cpu_seconds"`
+
+	// Seccomp opts into the syscall allowlist on Linux. It defaults to
+	// off because the allowlist is necessarily specific to how the
+	// daemon is configured to run (metrics listener, remote push, watch
+	// socket) -- turning it on unconditionally risks killing syscalls a
+	// given deployment actually needs.
+	Seccomp bool `json:"seccomp"`
+}
+
+// Apply enforces resource limits, drops privileges, and installs the
+// seccomp filter last, in that order: privilege drop needs geteuid,
+// setuid, and setgid, none of which are in allowedSyscalls, so it must
+// happen before the filter is installed rather than after. Seccomp then
+// locks down the process in its final, already-unprivileged state.
+// Privilege drop only runs when the process is actually root, since
+// Setuid/Setgid to an unprivileged user from an already-unprivileged
+// process just fails.
+func Apply(cfg Config) error {
+	if err := setResourceLimits(cfg); err != nil {
+		return fmt.Errorf("setting resource limits: %w", err)
+	}
+	if cfg.User != "" && os.Geteuid() == 0 {
+		if err := dropPrivileges(cfg.User); err != nil {
+			return fmt.Errorf("dropping privileges: %w", err)
+		}
+	}
+	if cfg.Seccomp {
+		if err := applySeccompFilter(); err != nil {
+			return fmt.Errorf("applying seccomp filter: %w", err)
+		}
+	}
+	return nil
+}
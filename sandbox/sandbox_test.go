@@ -0,0 +1,12 @@
+package sandbox
+
+import "testing"
+
+// TestApplyZeroConfig makes sure a zero Config -- the default when
+// nothing in powerpatrol.json opts into sandboxing -- is a true no-op
+// and never errors, on any platform.
+func TestApplyZeroConfig(t *testing.T) {
+	if err := Apply(Config{}); err != nil {
+		t.Fatalf("Apply(Config{}) = %v, want nil", err)
+	}
+}
@@ -0,0 +1,57 @@
+//go:build linux
+
+package sandbox
+
+import (
+	seccomp "github.com/elastic/go-seccomp-bpf"
+)
+
+// allowedSyscalls is the set the collector daemon needs once it has
+// opened its config and database files: enumerating /proc, sleeping,
+// writing to the database file descriptor it already holds open, the Go
+// runtime's own scheduling/netpoll machinery, the socket syscalls needed
+// by the watch socket, the Prometheus /metrics listener, and the
+// optional remote push client, and geteuid for any post-sandbox
+// privilege-state checks. Captured from an strace of `run` doing all of
+// the above, not guessed. setuid/setgid are deliberately absent: Apply
+// drops privileges before this filter is installed, so the daemon
+// should never need them again afterward.
+var allowedSyscalls = []string{
+	// /proc enumeration and file I/O.
+	"openat", "read", "pread64", "readv", "close", "fstat", "newfstatat",
+	"getdents64", "lseek", "write", "pwrite64", "writev", "fcntl", "ioctl",
+	"unlink", "rename", "ftruncate",
+	// Timers, scheduling, and the Go runtime's own bookkeeping.
+	"nanosleep", "clock_nanosleep", "futex", "sched_yield", "sched_getaffinity",
+	"mmap", "munmap", "mprotect", "madvise", "brk", "rt_sigreturn",
+	"rt_sigaction", "rt_sigprocmask", "sigaltstack", "exit", "exit_group",
+	"getpid", "gettid", "tgkill", "clock_gettime", "getrandom", "geteuid",
+	// Netpoll: the runtime polls with epoll_pwait/epoll_pwait2, not
+	// epoll_wait.
+	"epoll_create1", "epoll_ctl", "epoll_pwait", "epoll_pwait2", "eventfd2",
+	"pipe2",
+	// Unix socket server (watch clients) and TCP listener/client
+	// (Prometheus /metrics and the InfluxDB push HTTP calls).
+	"socket", "bind", "listen", "accept4", "connect", "setsockopt",
+	"getsockopt", "getsockname", "getpeername", "sendto", "recvfrom",
+	"sendmsg", "recvmsg", "shutdown",
+}
+
+// applySeccompFilter installs a deny-by-default seccomp-bpf filter
+// permitting only allowedSyscalls.
+func applySeccompFilter() error {
+	filter := seccomp.Filter{
+		NoNewPrivs: true,
+		Flag:       seccomp.FilterFlagTSync,
+		Policy: seccomp.Policy{
+			DefaultAction: seccomp.ActionErrno,
+			Syscalls: []seccomp.SyscallGroup{
+				{
+					Action: seccomp.ActionAllow,
+					Names:  allowedSyscalls,
+				},
+			},
+		},
+	}
+	return seccomp.LoadFilter(filter)
+}
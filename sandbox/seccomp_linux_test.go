@@ -0,0 +1,59 @@
+//go:build linux
+
+package sandbox
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"testing"
+)
+
+// TestSeccompFilterAllowsRealSyscalls applies the real seccomp filter in
+// a throwaway subprocess and exercises the syscalls `run` actually
+// depends on: enumerating /proc and opening a TCP socket. Installing the
+// filter is irreversible for the calling process, so it can't be done
+// directly in the shared test binary -- a missing syscall would take
+// every subsequent test down with it.
+func TestSeccompFilterAllowsRealSyscalls(t *testing.T) {
+	if os.Getenv("POWERPATROL_SECCOMP_HELPER") == "1" {
+		runSeccompHelper()
+		return
+	}
+
+	cmd := exec.Command(os.Args[0], "-test.run=TestSeccompFilterAllowsRealSyscalls")
+	cmd.Env = append(os.Environ(), "POWERPATROL_SECCOMP_HELPER=1")
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("seccomp helper failed: %v\n%s", err, out)
+	}
+}
+
+// runSeccompHelper installs the filter and then performs the syscalls
+// the daemon depends on once it's running: reading a directory
+// (getdents64, standing in for /proc enumeration), and opening and
+// closing a TCP socket (socket/bind/listen/close, needed by the metrics
+// listener and exercised the same way by the watch Unix socket server).
+// A fatal signal here means allowedSyscalls is missing something `run`
+// actually needs.
+func runSeccompHelper() {
+	if err := applySeccompFilter(); err != nil {
+		fail("applying seccomp filter: %v", err)
+	}
+
+	if _, err := os.ReadDir("/proc"); err != nil {
+		fail("reading /proc after seccomp filter: %v", err)
+	}
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		fail("listening on tcp after seccomp filter: %v", err)
+	}
+	ln.Close()
+}
+
+func fail(format string, args ...interface{}) {
+	fmt.Fprintf(os.Stderr, format+"\n", args...)
+	os.Exit(1)
+}
@@ -0,0 +1,9 @@
+//go:build !linux
+
+package sandbox
+
+// applySeccompFilter is a no-op outside Linux: seccomp-bpf has no
+// equivalent on other platforms.
+func applySeccompFilter() error {
+	return nil
+}
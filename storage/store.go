@@ -0,0 +1,251 @@
+// Package storage persists process usage samples to an embedded,
+// cgo-free SQLite database so `powerpatrol run` and `powerpatrol top` can
+// be separate processes that share data across daemon restarts. Samples
+// are downsampled over time: 1s resolution for the last hour, 1m
+// aggregates for the last day, and 1h aggregates beyond that.
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+// Sample is one process's usage reading at a point in time. It mirrors
+// the collector's ProcessUsage but lives here so this package doesn't
+// import back into main.
+type Sample struct {
+	Timestamp    time.Time
+	PID          int32
+	Name         string
+	CPUUsage     float64
+	MemUsage     float64
+	EnergyJoules float64
+	AvgWatts     float64
+}
+
+const schema = `
+CREATE TABLE IF NOT EXISTS samples (
+	timestamp     INTEGER NOT NULL,
+	pid           INTEGER NOT NULL,
+	name          TEXT    NOT NULL,
+	cpu_usage     REAL    NOT NULL,
+	mem_usage     REAL    NOT NULL,
+	energy_joules REAL    NOT NULL,
+	avg_watts     REAL    NOT NULL,
+	PRIMARY KEY (timestamp, pid, name)
+);
+CREATE INDEX IF NOT EXISTS idx_samples_timestamp ON samples(timestamp);
+
+CREATE TABLE IF NOT EXISTS samples_1m (
+	bucket        INTEGER NOT NULL,
+	pid           INTEGER NOT NULL,
+	name          TEXT    NOT NULL,
+	cpu_usage     REAL    NOT NULL,
+	mem_usage     REAL    NOT NULL,
+	energy_joules REAL    NOT NULL,
+	avg_watts     REAL    NOT NULL,
+	PRIMARY KEY (bucket, pid, name)
+);
+CREATE INDEX IF NOT EXISTS idx_samples_1m_bucket ON samples_1m(bucket);
+
+CREATE TABLE IF NOT EXISTS samples_1h (
+	bucket        INTEGER NOT NULL,
+	pid           INTEGER NOT NULL,
+	name          TEXT    NOT NULL,
+	cpu_usage     REAL    NOT NULL,
+	mem_usage     REAL    NOT NULL,
+	energy_joules REAL    NOT NULL,
+	avg_watts     REAL    NOT NULL,
+	PRIMARY KEY (bucket, pid, name)
+);
+CREATE INDEX IF NOT EXISTS idx_samples_1h_bucket ON samples_1h(bucket);
+`
+
+// Retention thresholds that decide which resolution a query should read
+// from, and how long each resolution is kept before it's rolled up or
+// dropped.
+const (
+	rawResolutionWindow = time.Hour
+	minuteRollupWindow  = 24 * time.Hour
+	minuteRetention     = 7 * 24 * time.Hour
+)
+
+// Store is a handle on the on-disk sample database.
+type Store struct {
+	db *sql.DB
+}
+
+// Open opens (creating if necessary) a read-write Store at path. It's
+// meant for the `run` daemon, the sole writer.
+func Open(path string) (*Store, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, fmt.Errorf("opening store: %w", err)
+	}
+	db.SetMaxOpenConns(1) // modernc.org/sqlite serializes writers anyway
+
+	if _, err := db.Exec(schema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("initializing schema: %w", err)
+	}
+
+	return &Store{db: db}, nil
+}
+
+// OpenReadOnly opens an existing Store at path without the ability to
+// write, for `top` to query a database a `run` daemon owns.
+func OpenReadOnly(path string) (*Store, error) {
+	db, err := sql.Open("sqlite", fmt.Sprintf("file:%s?mode=ro", path))
+	if err != nil {
+		return nil, fmt.Errorf("opening store read-only: %w", err)
+	}
+	return &Store{db: db}, nil
+}
+
+// Close closes the underlying database handle.
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// InsertSamples writes a batch of samples in a single transaction.
+func (s *Store) InsertSamples(samples []Sample) error {
+	if len(samples) == 0 {
+		return nil
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("beginning transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.Prepare(`
+		INSERT OR REPLACE INTO samples
+			(timestamp, pid, name, cpu_usage, mem_usage, energy_joules, avg_watts)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`)
+	if err != nil {
+		return fmt.Errorf("preparing insert: %w", err)
+	}
+	defer stmt.Close()
+
+	for _, sample := range samples {
+		if _, err := stmt.Exec(
+			sample.Timestamp.Unix(), sample.PID, sample.Name,
+			sample.CPUUsage, sample.MemUsage, sample.EnergyJoules, sample.AvgWatts,
+		); err != nil {
+			return fmt.Errorf("inserting sample for pid %d: %w", sample.PID, err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// Query returns samples covering [start, end]. Downsample keeps each
+// resolution table's data in its own non-overlapping age range -- raw
+// for the last hour, 1m aggregates for the last day, 1h aggregates
+// beyond that -- but a caller's window commonly spans more than one of
+// those ranges (e.g. `top --duration=2h` straddles raw and 1m data), so
+// Query reads all three tables and merges whatever each one has in
+// range, rather than picking a single table by the age of start alone.
+func (s *Store) Query(start, end time.Time) ([]Sample, error) {
+	raw, err := s.queryTable("samples", "timestamp", start, end)
+	if err != nil {
+		return nil, err
+	}
+	minute, err := s.queryTable("samples_1m", "bucket", start, end)
+	if err != nil {
+		return nil, err
+	}
+	hour, err := s.queryTable("samples_1h", "bucket", start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	samples := make([]Sample, 0, len(raw)+len(minute)+len(hour))
+	samples = append(samples, hour...)
+	samples = append(samples, minute...)
+	samples = append(samples, raw...)
+
+	sort.Slice(samples, func(i, j int) bool {
+		return samples[i].Timestamp.Before(samples[j].Timestamp)
+	})
+	return samples, nil
+}
+
+func (s *Store) queryTable(table, timeColumn string, start, end time.Time) ([]Sample, error) {
+	query := fmt.Sprintf(`
+		SELECT %s, pid, name, cpu_usage, mem_usage, energy_joules, avg_watts
+		FROM %s
+		WHERE %s BETWEEN ? AND ?
+		ORDER BY %s ASC
+	`, timeColumn, table, timeColumn, timeColumn)
+
+	rows, err := s.db.Query(query, start.Unix(), end.Unix())
+	if err != nil {
+		return nil, fmt.Errorf("querying %s: %w", table, err)
+	}
+	defer rows.Close()
+
+	var samples []Sample
+	for rows.Next() {
+		var ts int64
+		var sample Sample
+		if err := rows.Scan(&ts, &sample.PID, &sample.Name, &sample.CPUUsage, &sample.MemUsage, &sample.EnergyJoules, &sample.AvgWatts); err != nil {
+			return nil, fmt.Errorf("scanning %s row: %w", table, err)
+		}
+		sample.Timestamp = time.Unix(ts, 0)
+		samples = append(samples, sample)
+	}
+	return samples, rows.Err()
+}
+
+// Downsample rolls raw samples older than an hour up into 1m buckets,
+// 1m buckets older than a day up into 1h buckets, and prunes anything
+// older than retention. It's meant to be called periodically by the
+// `run` daemon, not by read-only queriers.
+func (s *Store) Downsample(now time.Time, retention time.Duration) error {
+	rawCutoff := now.Add(-rawResolutionWindow).Unix()
+	if _, err := s.db.Exec(`
+		INSERT OR REPLACE INTO samples_1m (bucket, pid, name, cpu_usage, mem_usage, energy_joules, avg_watts)
+		SELECT (timestamp / 60) * 60 AS bucket, pid, name,
+			AVG(cpu_usage), AVG(mem_usage), SUM(energy_joules), AVG(avg_watts)
+		FROM samples
+		WHERE timestamp < ?
+		GROUP BY bucket, pid, name
+	`, rawCutoff); err != nil {
+		return fmt.Errorf("rolling up to 1m: %w", err)
+	}
+	if _, err := s.db.Exec(`DELETE FROM samples WHERE timestamp < ?`, rawCutoff); err != nil {
+		return fmt.Errorf("pruning raw samples: %w", err)
+	}
+
+	minuteCutoff := now.Add(-minuteRollupWindow).Unix()
+	if _, err := s.db.Exec(`
+		INSERT OR REPLACE INTO samples_1h (bucket, pid, name, cpu_usage, mem_usage, energy_joules, avg_watts)
+		SELECT (bucket / 3600) * 3600 AS bucket, pid, name,
+			AVG(cpu_usage), AVG(mem_usage), SUM(energy_joules), AVG(avg_watts)
+		FROM samples_1m
+		WHERE bucket < ?
+		GROUP BY bucket, pid, name
+	`, minuteCutoff); err != nil {
+		return fmt.Errorf("rolling up to 1h: %w", err)
+	}
+	if _, err := s.db.Exec(`DELETE FROM samples_1m WHERE bucket < ?`, minuteCutoff); err != nil {
+		return fmt.Errorf("pruning 1m samples: %w", err)
+	}
+
+	if retention <= 0 {
+		retention = minuteRetention
+	}
+	retentionCutoff := now.Add(-retention).Unix()
+	if _, err := s.db.Exec(`DELETE FROM samples_1h WHERE bucket < ?`, retentionCutoff); err != nil {
+		return fmt.Errorf("pruning 1h samples: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,54 @@
+package storage
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// TestQueryMergesResolutions inserts data directly into all three
+// resolution tables -- simulating a store that's been running long
+// enough to have rolled up old data -- and checks that a window
+// spanning all of them returns data from each, instead of only the one
+// table picked by the age of start.
+func TestQueryMergesResolutions(t *testing.T) {
+	store, err := Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Open() = %v", err)
+	}
+	defer store.Close()
+
+	now := time.Now()
+	raw := Sample{Timestamp: now, PID: 1, Name: "raw", CPUUsage: 1}
+	if err := store.InsertSamples([]Sample{raw}); err != nil {
+		t.Fatalf("InsertSamples(raw) = %v", err)
+	}
+
+	if _, err := store.db.Exec(
+		`INSERT INTO samples_1m (bucket, pid, name, cpu_usage, mem_usage, energy_joules, avg_watts) VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		now.Add(-2*time.Hour).Unix(), 2, "minute", 2.0, 0.0, 0.0, 0.0,
+	); err != nil {
+		t.Fatalf("inserting into samples_1m: %v", err)
+	}
+	if _, err := store.db.Exec(
+		`INSERT INTO samples_1h (bucket, pid, name, cpu_usage, mem_usage, energy_joules, avg_watts) VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		now.Add(-30*24*time.Hour).Unix(), 3, "hour", 3.0, 0.0, 0.0, 0.0,
+	); err != nil {
+		t.Fatalf("inserting into samples_1h: %v", err)
+	}
+
+	samples, err := store.Query(now.Add(-31*24*time.Hour), now.Add(time.Minute))
+	if err != nil {
+		t.Fatalf("Query() = %v", err)
+	}
+
+	got := map[string]bool{}
+	for _, s := range samples {
+		got[s.Name] = true
+	}
+	for _, want := range []string{"raw", "minute", "hour"} {
+		if !got[want] {
+			t.Errorf("Query() missing %q sample, got %v", want, samples)
+		}
+	}
+}
@@ -0,0 +1,218 @@
+// Package tui implements the `watch` subcommand's live, htop-like
+// process table using bubbletea. It knows nothing about how samples are
+// collected -- it's fed a channel of batches and optional History/Kill
+// callbacks, so it works the same whether watch is attached to a running
+// `run` daemon or sampling processes itself.
+package tui
+
+import (
+	"regexp"
+	"sort"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/ksred/power-patrol/storage"
+)
+
+// SortKey selects which column the table is ordered by.
+type SortKey int
+
+const (
+	SortCPU SortKey = iota
+	SortMem
+	SortEnergy
+)
+
+// KillFunc sends a termination signal to pid; force selects SIGKILL over
+// SIGTERM.
+type KillFunc func(pid int32, force bool) error
+
+// HistoryFunc returns a single process's samples over the last window,
+// oldest first, for the sparkline column. A nil HistoryFunc disables it.
+type HistoryFunc func(pid int32, window time.Duration) ([]storage.Sample, error)
+
+// sparklineWindow is how far back History looks for the sparkline.
+const sparklineWindow = 2 * time.Minute
+
+// Options wires the TUI to its data source and actions.
+type Options struct {
+	Samples <-chan []storage.Sample
+	History HistoryFunc
+	Kill    KillFunc
+}
+
+// Run starts the interactive table and blocks until the user quits.
+func Run(opts Options) error {
+	p := tea.NewProgram(newModel(opts), tea.WithAltScreen())
+	_, err := p.Run()
+	return err
+}
+
+type model struct {
+	opts Options
+
+	rows    []storage.Sample
+	sortKey SortKey
+	cursor  int
+
+	filter      *regexp.Regexp
+	filtering   bool
+	filterInput string
+
+	showSparkline bool
+
+	confirmingKill bool
+	killForce      bool
+
+	err error
+}
+
+func newModel(opts Options) model {
+	return model{opts: opts, sortKey: SortCPU}
+}
+
+type samplesMsg []storage.Sample
+
+func waitForSamples(ch <-chan []storage.Sample) tea.Cmd {
+	return func() tea.Msg {
+		samples, ok := <-ch
+		if !ok {
+			return nil
+		}
+		return samplesMsg(samples)
+	}
+}
+
+func (m model) Init() tea.Cmd {
+	return waitForSamples(m.opts.Samples)
+}
+
+func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case samplesMsg:
+		m.rows = applyFilterAndSort([]storage.Sample(msg), m.filter, m.sortKey)
+		if m.cursor >= len(m.rows) {
+			m.cursor = len(m.rows) - 1
+		}
+		if m.cursor < 0 {
+			m.cursor = 0
+		}
+		return m, waitForSamples(m.opts.Samples)
+	case tea.KeyMsg:
+		return m.handleKey(msg)
+	}
+	return m, nil
+}
+
+func (m model) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.filtering {
+		return m.handleFilterKey(msg)
+	}
+	if m.confirmingKill {
+		return m.handleKillConfirmKey(msg)
+	}
+
+	switch msg.String() {
+	case "q", "ctrl+c":
+		return m, tea.Quit
+	case "up", "k":
+		if m.cursor > 0 {
+			m.cursor--
+		}
+	case "down", "j":
+		if m.cursor < len(m.rows)-1 {
+			m.cursor++
+		}
+	case "c":
+		m.sortKey = SortCPU
+	case "m":
+		m.sortKey = SortMem
+	case "e":
+		m.sortKey = SortEnergy
+	case "/":
+		m.filtering = true
+		m.filterInput = ""
+	case "s":
+		m.showSparkline = !m.showSparkline
+	case "x":
+		if m.opts.Kill != nil && len(m.rows) > 0 {
+			m.confirmingKill = true
+			m.killForce = false
+		}
+	case "X":
+		if m.opts.Kill != nil && len(m.rows) > 0 {
+			m.confirmingKill = true
+			m.killForce = true
+		}
+	}
+	return m, nil
+}
+
+func (m model) handleFilterKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyEnter:
+		m.filtering = false
+		if m.filterInput == "" {
+			m.filter = nil
+			return m, nil
+		}
+		re, err := regexp.Compile(m.filterInput)
+		if err != nil {
+			m.err = err
+			return m, nil
+		}
+		m.filter = re
+		m.err = nil
+	case tea.KeyEsc:
+		m.filtering = false
+		m.filterInput = ""
+	case tea.KeyBackspace:
+		if len(m.filterInput) > 0 {
+			m.filterInput = m.filterInput[:len(m.filterInput)-1]
+		}
+	default:
+		m.filterInput += msg.String()
+	}
+	return m, nil
+}
+
+func (m model) handleKillConfirmKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	m.confirmingKill = false
+	if msg.String() != "y" {
+		return m, nil
+	}
+	if m.cursor < len(m.rows) && m.opts.Kill != nil {
+		if err := m.opts.Kill(m.rows[m.cursor].PID, m.killForce); err != nil {
+			m.err = err
+		}
+	}
+	return m, nil
+}
+
+func applyFilterAndSort(samples []storage.Sample, filter *regexp.Regexp, key SortKey) []storage.Sample {
+	rows := samples
+	if filter != nil {
+		filtered := make([]storage.Sample, 0, len(samples))
+		for _, s := range samples {
+			if filter.MatchString(s.Name) {
+				filtered = append(filtered, s)
+			}
+		}
+		rows = filtered
+	}
+
+	sorted := make([]storage.Sample, len(rows))
+	copy(sorted, rows)
+	sort.Slice(sorted, func(i, j int) bool {
+		switch key {
+		case SortMem:
+			return sorted[i].MemUsage > sorted[j].MemUsage
+		case SortEnergy:
+			return sorted[i].EnergyJoules > sorted[j].EnergyJoules
+		default:
+			return sorted[i].CPUUsage > sorted[j].CPUUsage
+		}
+	})
+	return sorted
+}
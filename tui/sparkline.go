@@ -0,0 +1,39 @@
+package tui
+
+import "github.com/ksred/power-patrol/storage"
+
+// sparkBlocks are the block-element glyphs used to render a sparkline,
+// lowest to highest.
+var sparkBlocks = []rune("▁▂▃▄▅▆▇█")
+
+// sparkline renders samples' CPU usage as a string of block characters
+// scaled to the highest value seen, so a process's recent trend fits in
+// a single table cell.
+func sparkline(samples []storage.Sample) string {
+	if len(samples) == 0 {
+		return ""
+	}
+
+	max := samples[0].CPUUsage
+	for _, s := range samples {
+		if s.CPUUsage > max {
+			max = s.CPUUsage
+		}
+	}
+	if max <= 0 {
+		max = 1
+	}
+
+	out := make([]rune, len(samples))
+	for i, s := range samples {
+		idx := int((s.CPUUsage / max) * float64(len(sparkBlocks)-1))
+		if idx < 0 {
+			idx = 0
+		}
+		if idx >= len(sparkBlocks) {
+			idx = len(sparkBlocks) - 1
+		}
+		out[i] = sparkBlocks[idx]
+	}
+	return string(out)
+}
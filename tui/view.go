@@ -0,0 +1,78 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+var (
+	headerStyle   = lipgloss.NewStyle().Bold(true)
+	selectedStyle = lipgloss.NewStyle().Reverse(true)
+	helpStyle     = lipgloss.NewStyle().Faint(true)
+	errStyle      = lipgloss.NewStyle().Foreground(lipgloss.Color("9"))
+)
+
+func (m model) View() string {
+	var b strings.Builder
+
+	b.WriteString(headerStyle.Render(fmt.Sprintf("%-7s %-25s %8s %8s %8s %8s", "PID", "NAME", "CPU%", "MEM%", "WATTS", "JOULES")))
+	if m.showSparkline {
+		b.WriteString("  " + headerStyle.Render("TREND"))
+	}
+	b.WriteString("\n")
+
+	for i, row := range m.rows {
+		line := fmt.Sprintf("%-7d %-25s %8.2f %8.2f %8.2f %8.2f",
+			row.PID, truncate(row.Name, 25), row.CPUUsage, row.MemUsage, row.AvgWatts, row.EnergyJoules)
+		if m.showSparkline {
+			line += "  " + m.renderSparkline(row.PID)
+		}
+		if i == m.cursor {
+			line = selectedStyle.Render(line)
+		}
+		b.WriteString(line + "\n")
+	}
+
+	b.WriteString("\n")
+	b.WriteString(helpStyle.Render(m.footer()))
+
+	if m.err != nil {
+		b.WriteString("\n" + errStyle.Render(m.err.Error()))
+	}
+
+	return b.String()
+}
+
+func (m model) renderSparkline(pid int32) string {
+	if m.opts.History == nil {
+		return ""
+	}
+	samples, err := m.opts.History(pid, sparklineWindow)
+	if err != nil {
+		return ""
+	}
+	return sparkline(samples)
+}
+
+func (m model) footer() string {
+	if m.filtering {
+		return "filter: " + m.filterInput + "_"
+	}
+	if m.confirmingKill {
+		verb := "SIGTERM"
+		if m.killForce {
+			verb = "SIGKILL"
+		}
+		return fmt.Sprintf("send %s to pid %d? (y/N)", verb, m.rows[m.cursor].PID)
+	}
+	return "↑/↓ navigate · c/m/e sort · / filter · s sparkline · x/X kill · q quit"
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n-1] + "…"
+}